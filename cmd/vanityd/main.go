@@ -0,0 +1,54 @@
+// Copyright 2018 Andrei Tudor Călin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command vanityd serves vanity Go import paths from a configuration
+// file, reloading the routing table whenever the file changes.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"net/http"
+
+	"acln.ro/vanity"
+)
+
+func main() {
+	var (
+		addr       = flag.String("addr", ":8080", "address to listen on")
+		configPath = flag.String("config", "vanity.yaml", "path to the vanity configuration file")
+		watch      = flag.Bool("watch", true, "reload the configuration file on changes")
+	)
+	flag.Parse()
+
+	h, err := vanity.LoadConfig(*configPath)
+	if err != nil {
+		log.Fatalf("vanityd: %v", err)
+	}
+
+	if *watch {
+		go func() {
+			err := vanity.Watch(context.Background(), *configPath, h, func(err error) {
+				log.Printf("vanityd: reloading %s: %v", *configPath, err)
+			})
+			if err != nil {
+				log.Printf("vanityd: stopped watching %s: %v", *configPath, err)
+			}
+		}()
+	}
+
+	log.Printf("vanityd: serving %s on %s", *configPath, *addr)
+	log.Fatal(http.ListenAndServe(*addr, h))
+}
@@ -0,0 +1,148 @@
+// Copyright 2018 Andrei Tudor Călin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vanity
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v2"
+)
+
+// Config describes a declarative configuration for a Handler. It is
+// intended to be loaded from a JSON, YAML, or TOML file using
+// LoadConfig.
+type Config struct {
+	Paths []PathConfig `json:"paths" yaml:"paths" toml:"paths"`
+}
+
+// PathConfig describes a single ImportPath entry in a Config.
+type PathConfig struct {
+	From     string `json:"from" yaml:"from" toml:"from"`
+	To       string `json:"to" yaml:"to" toml:"to"`
+	VCS      string `json:"vcs" yaml:"vcs" toml:"vcs"`
+	Wildcard bool   `json:"wildcard" yaml:"wildcard" toml:"wildcard"`
+
+	// Proxy configures ImportPath.Proxy, for VCS: "mod" entries.
+	Proxy string `json:"proxy,omitempty" yaml:"proxy,omitempty" toml:"proxy,omitempty"`
+
+	// GodocRedirect selects where interactive requests matching this
+	// path are redirected to: "godoc.org" (the default) or
+	// "pkg.go.dev".
+	GodocRedirect string `json:"godoc_redirect,omitempty" yaml:"godoc_redirect,omitempty" toml:"godoc_redirect,omitempty"`
+
+	// Source, if set, configures a go-source meta tag for this path.
+	Source *SourceConfig `json:"go_source,omitempty" yaml:"go_source,omitempty" toml:"go_source,omitempty"`
+}
+
+// SourceConfig describes the go-source meta tag fields for a
+// PathConfig, beyond the prefix, which is always PathConfig.From.
+type SourceConfig struct {
+	Home      string `json:"home" yaml:"home" toml:"home"`
+	Directory string `json:"directory" yaml:"directory" toml:"directory"`
+	File      string `json:"file" yaml:"file" toml:"file"`
+}
+
+// ImportPath converts c to an ImportPath.
+func (c PathConfig) ImportPath() (ImportPath, error) {
+	redirect, err := c.redirectFunc()
+	if err != nil {
+		return ImportPath{}, err
+	}
+	ip := ImportPath{
+		VCS:      c.VCS,
+		From:     c.From,
+		To:       c.To,
+		Wildcard: c.Wildcard,
+		Proxy:    c.Proxy,
+		Redirect: redirect,
+	}
+	if c.Source != nil {
+		ip.Source = &SourceTag{
+			Prefix:    c.From,
+			Home:      c.Source.Home,
+			Directory: c.Source.Directory,
+			File:      c.Source.File,
+		}
+	}
+	return ip, nil
+}
+
+func (c PathConfig) redirectFunc() (func(w http.ResponseWriter, req *http.Request), error) {
+	switch c.GodocRedirect {
+	case "":
+		return nil, nil
+	case "godoc.org":
+		return RedirectToGodoc, nil
+	case "pkg.go.dev":
+		return RedirectToPkgGoDev, nil
+	default:
+		return nil, fmt.Errorf("vanity: unknown godoc_redirect target %q", c.GodocRedirect)
+	}
+}
+
+// LoadConfig reads the configuration file at path, in JSON, YAML, or
+// TOML format (as determined by its extension), and returns a Handler
+// built from it.
+func LoadConfig(path string) (*Handler, error) {
+	cfg, err := readConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	paths, err := cfg.paths()
+	if err != nil {
+		return nil, err
+	}
+	return NewServeMux(paths...), nil
+}
+
+func readConfig(path string) (*Config, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cfg := new(Config)
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+		err = json.Unmarshal(data, cfg)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, cfg)
+	case ".toml":
+		err = toml.Unmarshal(data, cfg)
+	default:
+		return nil, fmt.Errorf("vanity: unrecognized config file extension %q", ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("vanity: parsing %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+func (cfg *Config) paths() ([]ImportPath, error) {
+	paths := make([]ImportPath, len(cfg.Paths))
+	for i, pc := range cfg.Paths {
+		ip, err := pc.ImportPath()
+		if err != nil {
+			return nil, err
+		}
+		paths[i] = ip
+	}
+	return paths, nil
+}
@@ -0,0 +1,140 @@
+// Copyright 2018 Andrei Tudor Călin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vanity_test
+
+import (
+	"crypto/tls"
+	"io/ioutil"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"acln.ro/vanity"
+)
+
+func writeFile(path, content string) error {
+	return ioutil.WriteFile(path, []byte(content), 0o644)
+}
+
+func tlsConnectionState() *tls.ConnectionState {
+	return &tls.ConnectionState{}
+}
+
+func TestLoadConfigJSON(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "vanity.json")
+	const config = `{
+		"paths": [
+			{
+				"from": "acln.ro/foo",
+				"to": "https://github.com/acln0/foo",
+				"vcs": "git",
+				"go_source": {
+					"home": "https://github.com/acln0/foo",
+					"directory": "https://github.com/acln0/foo/tree/master{/dir}",
+					"file": "https://github.com/acln0/foo/blob/master{/dir}/{file}#L{line}"
+				}
+			}
+		]
+	}`
+	if err := writeFile(configPath, config); err != nil {
+		t.Fatal(err)
+	}
+
+	h, err := vanity.LoadConfig(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest("GET", "https://acln.ro/foo?go-get=1", nil)
+	req.TLS = tlsConnectionState()
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	want := `<meta name="go-source" content="acln.ro/foo https://github.com/acln0/foo https://github.com/acln0/foo/tree/master{/dir} https://github.com/acln0/foo/blob/master{/dir}/{file}#L{line}">`
+	if !strings.Contains(w.Body.String(), want) {
+		t.Fatalf("%s\ndoes not contain\n%s", w.Body.String(), want)
+	}
+}
+
+func TestLoadConfigYAML(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "vanity.yaml")
+	const config = `
+paths:
+  - from: acln.ro/foo
+    to: https://github.com/acln0/foo
+    vcs: git
+`
+	if err := writeFile(configPath, config); err != nil {
+		t.Fatal(err)
+	}
+
+	h, err := vanity.LoadConfig(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest("GET", "https://acln.ro/foo?go-get=1", nil)
+	req.TLS = tlsConnectionState()
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	want := `content="acln.ro/foo git https://github.com/acln0/foo"`
+	if !strings.Contains(w.Body.String(), want) {
+		t.Fatalf("%s\ndoes not contain\n%s", w.Body.String(), want)
+	}
+}
+
+func TestLoadConfigTOML(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "vanity.toml")
+	const config = `
+[[paths]]
+from = "acln.ro/foo"
+to = "https://github.com/acln0/foo"
+vcs = "git"
+`
+	if err := writeFile(configPath, config); err != nil {
+		t.Fatal(err)
+	}
+
+	h, err := vanity.LoadConfig(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest("GET", "https://acln.ro/foo?go-get=1", nil)
+	req.TLS = tlsConnectionState()
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	want := `content="acln.ro/foo git https://github.com/acln0/foo"`
+	if !strings.Contains(w.Body.String(), want) {
+		t.Fatalf("%s\ndoes not contain\n%s", w.Body.String(), want)
+	}
+}
+
+func TestLoadConfigUnknownExtension(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "vanity.ini")
+	if err := writeFile(configPath, ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := vanity.LoadConfig(configPath); err == nil {
+		t.Fatal("got nil error for unrecognized config extension, want error")
+	}
+}
@@ -0,0 +1,30 @@
+// Copyright 2018 Andrei Tudor Călin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vanity
+
+import "errors"
+
+// ErrPathMismatch is returned by TagFor and WildcardTagFor when the
+// request does not target the ImportPath at all. Callers composing
+// several ImportPath values, such as Handler, treat it as a signal to
+// try the next candidate rather than a hard failure.
+var ErrPathMismatch = errors.New("vanity: request path does not match import path")
+
+// ErrEmptyWildcardSegment is returned by WildcardTagFor when the
+// request leaves no child segment to build a wildcard import path
+// from: both ip.From and req.Host are empty and the request path is
+// the root. A request for exactly ip.From, with or without a trailing
+// slash, yields ErrPathMismatch instead.
+var ErrEmptyWildcardSegment = errors.New("vanity: no child segment beyond wildcard import path")
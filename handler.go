@@ -0,0 +1,181 @@
+// Copyright 2018 Andrei Tudor Călin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vanity
+
+import (
+	"net/http"
+	"net/url"
+	"sort"
+	"sync/atomic"
+)
+
+// Handler is an http.Handler that serves go-import (and, optionally,
+// go-source) meta tags for a set of ImportPath mappings, implementing
+// the go tool's remote import path discovery protocol.
+//
+// For requests identified as go-get requests by IsGoGet, Handler emits
+// the meta tag(s) for the matching ImportPath. For all other requests
+// matching an ImportPath, Handler calls Redirect, so that interactive
+// visitors end up on a documentation page instead of a bare meta tag
+// page. Requests that match no ImportPath receive a 404. Go-get
+// requests not made over HTTPS, directly or as reported by an
+// X-Forwarded-Proto header, receive a 403.
+//
+// Requests following the Go module proxy protocol (@v/list, @latest,
+// @v/{version}.info|.mod|.zip) are proxied to the ImportPath's Proxy,
+// if set, regardless of IsGoGet.
+//
+// The set of paths served by a Handler may be replaced at any time by
+// calling SetPaths; in-flight requests continue to be served against
+// the routing table that was in effect when they arrived.
+type Handler struct {
+	paths atomic.Value // []ImportPath
+
+	// Redirect is called for requests that are not go-get requests, and
+	// whose matching ImportPath does not set its own Redirect. If nil,
+	// RedirectToGodoc is used.
+	Redirect func(w http.ResponseWriter, req *http.Request)
+
+	// Logger, if non-nil, receives one log line per served request.
+	Logger Logger
+
+	// Metrics, if non-nil, receives counters about served requests.
+	Metrics Metrics
+}
+
+// NewServeMux builds a Handler serving the given import paths.
+func NewServeMux(paths ...ImportPath) *Handler {
+	h := new(Handler)
+	h.SetPaths(paths...)
+	return h
+}
+
+// SetPaths atomically replaces the set of ImportPath mappings served by
+// h. Paths are matched using longest-prefix matching on ImportPath.From,
+// so SetPaths sorts a copy of paths accordingly; it does not modify the
+// slice passed in.
+func (h *Handler) SetPaths(paths ...ImportPath) {
+	sorted := make([]ImportPath, len(paths))
+	copy(sorted, paths)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return len(sorted[i].From) > len(sorted[j].From)
+	})
+	h.paths.Store(sorted)
+}
+
+func (h *Handler) routes() []ImportPath {
+	paths, _ := h.paths.Load().([]ImportPath)
+	return paths
+}
+
+// ServeHTTP implements http.Handler.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+	matched := ""
+	defer func() {
+		h.logf("%s %s%s -> %d (import path %q)", req.Method, req.Host, req.URL.Path, sw.status, matched)
+		if h.Metrics != nil {
+			h.Metrics.IncRequests(matched, IsGoGet(req), sw.status)
+		}
+	}()
+
+	if IsGoGet(req) && !isSecure(req) {
+		http.Error(sw, "go-get requests must use HTTPS", http.StatusForbidden)
+		return
+	}
+	routes := h.routes()
+	for _, ip := range routes {
+		if target, ok := ip.proxyTarget(req); ok {
+			matched = ip.From
+			proxyModule(sw, req, target)
+			return
+		}
+	}
+	for _, ip := range routes {
+		tagFor := ip.TagFor
+		if ip.Wildcard {
+			tagFor = ip.WildcardTagFor
+		}
+		tag, err := tagFor(req)
+		if err != nil {
+			continue
+		}
+		matched = tag.ImportPath
+		if !IsGoGet(req) {
+			h.redirect(sw, req, ip, tag.ImportPath)
+			return
+		}
+		sw.Header().Set("Content-Type", "text/html; charset=utf-8")
+		tag.Render(sw)
+		return
+	}
+	http.NotFound(sw, req)
+}
+
+func (h *Handler) redirect(w http.ResponseWriter, req *http.Request, ip ImportPath, importPath string) {
+	redirectFunc := RedirectToGodoc
+	switch {
+	case ip.Redirect != nil:
+		redirectFunc = ip.Redirect
+	case h.Redirect != nil:
+		redirectFunc = h.Redirect
+	}
+	redirectFunc(w, req)
+	if h.Metrics != nil {
+		h.Metrics.IncRedirects(importPath, redirectHost(w))
+	}
+}
+
+// redirectHost extracts the host of the Location header set by a
+// redirect func, for metrics purposes.
+func redirectHost(w http.ResponseWriter) string {
+	loc := w.Header().Get("Location")
+	if loc == "" {
+		return ""
+	}
+	u, err := url.Parse(loc)
+	if err != nil {
+		return ""
+	}
+	return u.Host
+}
+
+func (h *Handler) logf(format string, args ...interface{}) {
+	if h.Logger != nil {
+		h.Logger.Printf(format, args...)
+	}
+}
+
+// isSecure reports whether req was received over HTTPS, either
+// directly or as indicated by a reverse proxy via the de facto standard
+// X-Forwarded-Proto header.
+func isSecure(req *http.Request) bool {
+	if req.TLS != nil {
+		return true
+	}
+	return req.Header.Get("X-Forwarded-Proto") == "https"
+}
+
+// statusWriter wraps an http.ResponseWriter, recording the status code
+// of the response for observability purposes.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
@@ -0,0 +1,203 @@
+// Copyright 2018 Andrei Tudor Călin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vanity_test
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"acln.ro/vanity"
+)
+
+func TestHandlerGoGet(t *testing.T) {
+	h := vanity.NewServeMux(
+		vanity.ImportPath{
+			VCS:  "git",
+			From: "acln.ro/foo",
+			To:   "https://github.com/acln0/foo",
+		},
+		vanity.ImportPath{
+			VCS:      "git",
+			From:     "acln.ro",
+			To:       "https://github.com/acln0",
+			Wildcard: true,
+		},
+	)
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"acln.ro/foo", "acln.ro/foo git https://github.com/acln0/foo"},
+		{"acln.ro/foo/bar", "acln.ro/foo git https://github.com/acln0/foo"},
+		{"acln.ro/bar", "acln.ro/bar git https://github.com/acln0/bar"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "https://"+tt.path+"?go-get=1", nil)
+		req.TLS = &tls.ConnectionState{}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		resp := w.Result()
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("%s: got status %d, want %d", tt.path, resp.StatusCode, http.StatusOK)
+		}
+		if !strings.Contains(w.Body.String(), tt.want) {
+			t.Errorf("%s: body %q does not contain %q", tt.path, w.Body.String(), tt.want)
+		}
+	}
+}
+
+func TestHandlerInteractiveRedirect(t *testing.T) {
+	h := vanity.NewServeMux(vanity.ImportPath{
+		VCS:  "git",
+		From: "acln.ro/foo",
+		To:   "https://github.com/acln0/foo",
+	})
+	req := httptest.NewRequest("GET", "https://acln.ro/foo", nil)
+	req.TLS = &tls.ConnectionState{}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	resp := w.Result()
+	if resp.StatusCode != http.StatusFound {
+		t.Errorf("got status %d, want %d", resp.StatusCode, http.StatusFound)
+	}
+	wantLocation := "https://godoc.org/acln.ro/foo"
+	if got := resp.Header.Get("Location"); got != wantLocation {
+		t.Errorf("got location %s, want %s", got, wantLocation)
+	}
+}
+
+func TestHandlerNotFound(t *testing.T) {
+	h := vanity.NewServeMux(vanity.ImportPath{
+		VCS:  "git",
+		From: "acln.ro/foo",
+		To:   "https://github.com/acln0/foo",
+	})
+	req := httptest.NewRequest("GET", "https://acln.ro/bar?go-get=1", nil)
+	req.TLS = &tls.ConnectionState{}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	resp := w.Result()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("got status %d, want %d", resp.StatusCode, http.StatusNotFound)
+	}
+}
+
+func TestHandlerModuleProxyGoImportTag(t *testing.T) {
+	// The advertised mod target must always match Proxy, the upstream
+	// that @v/list and friends are actually forwarded to, even if To
+	// is set to something else (or left stale).
+	h := vanity.NewServeMux(vanity.ImportPath{
+		VCS:   "mod",
+		From:  "acln.ro/foo",
+		To:    "https://WRONG.example.invalid",
+		Proxy: "https://proxy.golang.org",
+	})
+	req := httptest.NewRequest("GET", "https://acln.ro/foo?go-get=1", nil)
+	req.TLS = &tls.ConnectionState{}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	want := `content="acln.ro/foo mod https://proxy.golang.org"`
+	if !strings.Contains(w.Body.String(), want) {
+		t.Fatalf("%s\ndoes not contain\n%s", w.Body.String(), want)
+	}
+}
+
+func TestHandlerModuleProxy(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/acln.ro/foo/@v/list" {
+			t.Errorf("upstream got path %s", r.URL.Path)
+		}
+		w.Write([]byte("v1.0.0\n"))
+	}))
+	defer upstream.Close()
+
+	h := vanity.NewServeMux(vanity.ImportPath{
+		VCS:   "mod",
+		From:  "acln.ro/foo",
+		To:    upstream.URL,
+		Proxy: upstream.URL,
+	})
+
+	// Build the request the way real GOPROXY traffic looks: the path is
+	// the full, domain-qualified module path, independent of Host (which
+	// may well be a different domain, as with proxy.golang.org).
+	req := httptest.NewRequest("GET", "/acln.ro/foo/@v/list", nil)
+	req.Host = "acln.ro"
+	req.TLS = &tls.ConnectionState{}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	resp := w.Result()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("got status %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	if got := w.Body.String(); got != "v1.0.0\n" {
+		t.Errorf("got body %q, want %q", got, "v1.0.0\n")
+	}
+}
+
+type fakeMetrics struct {
+	requests  []string
+	redirects []string
+}
+
+func (m *fakeMetrics) IncRequests(importPath string, goGet bool, status int) {
+	m.requests = append(m.requests, importPath)
+}
+
+func (m *fakeMetrics) IncRedirects(importPath, redirectHost string) {
+	m.redirects = append(m.redirects, importPath+" -> "+redirectHost)
+}
+
+func TestHandlerMetrics(t *testing.T) {
+	metrics := new(fakeMetrics)
+	h := vanity.NewServeMux(vanity.ImportPath{
+		VCS:  "git",
+		From: "acln.ro/foo",
+		To:   "https://github.com/acln0/foo",
+	})
+	h.Metrics = metrics
+
+	req := httptest.NewRequest("GET", "https://acln.ro/foo", nil)
+	req.TLS = &tls.ConnectionState{}
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	if want := []string{"acln.ro/foo"}; !reflect.DeepEqual(metrics.requests, want) {
+		t.Errorf("got requests %v, want %v", metrics.requests, want)
+	}
+	if want := []string{"acln.ro/foo -> godoc.org"}; !reflect.DeepEqual(metrics.redirects, want) {
+		t.Errorf("got redirects %v, want %v", metrics.redirects, want)
+	}
+}
+
+func TestHandlerRequiresHTTPS(t *testing.T) {
+	h := vanity.NewServeMux(vanity.ImportPath{
+		VCS:  "git",
+		From: "acln.ro/foo",
+		To:   "https://github.com/acln0/foo",
+	})
+	req := httptest.NewRequest("GET", "http://acln.ro/foo?go-get=1", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	resp := w.Result()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("got status %d, want %d", resp.StatusCode, http.StatusForbidden)
+	}
+}
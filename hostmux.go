@@ -0,0 +1,119 @@
+// Copyright 2018 Andrei Tudor Călin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vanity
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// HostMux is an http.Handler that dispatches requests to a per-host
+// Handler, based on req.Host. It allows a single process to serve
+// multiple vanity hostnames, each with its own routing table.
+//
+// Hosts may be registered as exact matches ("example.com") or as
+// wildcards ("*.example.com"), matching any subdomain. Exact matches
+// take precedence over wildcards; among wildcards, the most specific
+// (longest) suffix wins.
+type HostMux struct {
+	mu        sync.RWMutex
+	exact     map[string]*Handler
+	wildcards []wildcardHost
+}
+
+type wildcardHost struct {
+	suffix  string // e.g. ".example.com"
+	handler *Handler
+}
+
+// NewHostMux returns an empty HostMux.
+func NewHostMux() *HostMux {
+	return &HostMux{exact: make(map[string]*Handler)}
+}
+
+// Handle registers h to serve requests for host. host may be an exact
+// hostname, or a wildcard of the form "*.example.com", matching any
+// subdomain of example.com.
+func (m *HostMux) Handle(host string, h *Handler) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if suffix := strings.TrimPrefix(host, "*."); suffix != host {
+		m.wildcards = append(m.wildcards, wildcardHost{suffix: "." + suffix, handler: h})
+		sort.SliceStable(m.wildcards, func(i, j int) bool {
+			return len(m.wildcards[i].suffix) > len(m.wildcards[j].suffix)
+		})
+		return
+	}
+	m.exact[host] = h
+}
+
+// HostDefaults describes the common case of mapping every import path
+// under a vanity host to the identically named repository under a
+// single upstream organization, e.g. "mydomain.tld/foo" to
+// "github.com/myorg/foo".
+type HostDefaults struct {
+	// VCS is the version control system for packages under this host.
+	VCS string
+
+	// Upstream is the base URL that import paths under this host are
+	// rooted at, e.g. "https://github.com/myorg".
+	Upstream string
+
+	// Redirect, if non-nil, is used for interactive requests to this
+	// host.
+	Redirect func(w http.ResponseWriter, req *http.Request)
+}
+
+// HandleDefaults registers a wildcard Handler for host, mapping every
+// import path under host to the same path under d.Upstream. Since host
+// may itself be a wildcard such as "*.example.com", the ImportPath
+// registered leaves From empty, matching whatever host the request
+// actually arrived on; see WildcardTagFor.
+func (m *HostMux) HandleDefaults(host string, d HostDefaults) {
+	m.Handle(host, NewServeMux(ImportPath{
+		VCS:      d.VCS,
+		To:       d.Upstream,
+		Wildcard: true,
+		Redirect: d.Redirect,
+	}))
+}
+
+func (m *HostMux) handler(host string) *Handler {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if h, ok := m.exact[host]; ok {
+		return h
+	}
+	for _, w := range m.wildcards {
+		if strings.HasSuffix(host, w.suffix) {
+			return w.handler
+		}
+	}
+	return nil
+}
+
+// ServeHTTP implements http.Handler. It dispatches req to the Handler
+// registered for req.Host, ignoring any port suffix, and responds 404
+// if no Handler is registered for the host.
+func (m *HostMux) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	h := m.handler(stripPort(req.Host))
+	if h == nil {
+		http.NotFound(w, req)
+		return
+	}
+	h.ServeHTTP(w, req)
+}
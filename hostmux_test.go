@@ -0,0 +1,70 @@
+// Copyright 2018 Andrei Tudor Călin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vanity_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"acln.ro/vanity"
+)
+
+func TestHostMux(t *testing.T) {
+	m := vanity.NewHostMux()
+	m.Handle("acln.ro", vanity.NewServeMux(vanity.ImportPath{
+		VCS:  "git",
+		From: "acln.ro/foo",
+		To:   "https://github.com/acln0/foo",
+	}))
+	m.HandleDefaults("*.example.com", vanity.HostDefaults{
+		VCS:      "git",
+		Upstream: "https://github.com/example",
+	})
+
+	tests := []struct {
+		host string
+		path string
+		// want is the exact go-import meta tag content expected, double
+		// slashes in VCSRepo included: this pins down HandleDefaults'
+		// wildcard ImportPath against the VCSRepo path.Join bug, where
+		// the "//" in an https:// upstream URL got collapsed to "/".
+		want string
+		code int
+	}{
+		{"acln.ro", "/foo", `content="acln.ro/foo git https://github.com/acln0/foo"`, http.StatusOK},
+		{"pkg.example.com", "/bar", `content="pkg.example.com/bar git https://github.com/example/bar"`, http.StatusOK},
+		// HostMux ignores any port in Host when dispatching, and
+		// WildcardTagFor's dynamic-host fallback strips it as well, so
+		// the rendered import path never carries one either.
+		{"pkg.example.com:8443", "/bar", `content="pkg.example.com/bar git https://github.com/example/bar"`, http.StatusOK},
+		{"other.tld", "/foo", "", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "https://"+tt.host+tt.path+"?go-get=1", nil)
+		req.Host = tt.host
+		req.TLS = tlsConnectionState()
+		w := httptest.NewRecorder()
+		m.ServeHTTP(w, req)
+		resp := w.Result()
+		if resp.StatusCode != tt.code {
+			t.Errorf("%s%s: got status %d, want %d", tt.host, tt.path, resp.StatusCode, tt.code)
+		}
+		if tt.want != "" && !strings.Contains(w.Body.String(), tt.want) {
+			t.Errorf("%s%s: body %q does not contain %q", tt.host, tt.path, w.Body.String(), tt.want)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+// Copyright 2018 Andrei Tudor Călin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vanity
+
+// Metrics receives counters about requests served by a Handler. Its
+// methods mirror the labels of a prometheus CounterVec, so a Metrics
+// implementation is typically a thin adapter around one or more
+// *prometheus.CounterVec values; the interface itself has no
+// dependency on any particular metrics library.
+type Metrics interface {
+	// IncRequests increments a counter for one served request, labeled
+	// by the matched import path (empty if no ImportPath matched),
+	// whether the request was a go-get request, and the HTTP status
+	// code returned.
+	IncRequests(importPath string, goGet bool, status int)
+
+	// IncRedirects increments a counter for one interactive redirect,
+	// labeled by the matched import path and the host redirected to,
+	// e.g. "godoc.org" or "pkg.go.dev".
+	IncRedirects(importPath, redirectHost string)
+}
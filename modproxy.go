@@ -0,0 +1,73 @@
+// Copyright 2018 Andrei Tudor Călin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vanity
+
+import (
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// moduleProxyPath splits a request path into a module path and a Go
+// module proxy protocol suffix, as documented at
+// https://go.dev/ref/mod#goproxy-protocol.
+var moduleProxyPath = regexp.MustCompile(`^(.+)(/@(?:v/list|latest|v/[^/]+\.(?:info|mod|zip)))$`)
+
+// proxyTarget returns the upstream URL that a module proxy protocol
+// request for req should be forwarded to, if ip is configured with a
+// Proxy and req matches ip's import path.
+//
+// Unlike TagFor and WildcardTagFor, the match is made purely against
+// req.URL.Path, not req.Host plus the path: per the Go module proxy
+// protocol, the request path is already the full, domain-qualified
+// module path, unrelated to the Host the request happens to arrive on
+// (that's the point of proxy.golang.org/rsc.io/quote/@v/list serving a
+// module under a completely different domain).
+func (ip ImportPath) proxyTarget(req *http.Request) (string, bool) {
+	if ip.Proxy == "" {
+		return "", false
+	}
+	full := strings.TrimPrefix(req.URL.Path, "/")
+	m := moduleProxyPath.FindStringSubmatch(full)
+	if m == nil {
+		return "", false
+	}
+	modPath, suffix := m[1], m[2]
+	if modPath != ip.From && !(ip.Wildcard && strings.HasPrefix(modPath, ip.From+"/")) {
+		return "", false
+	}
+	return strings.TrimSuffix(ip.Proxy, "/") + "/" + modPath + suffix, true
+}
+
+// proxyModule forwards req to target, a fully qualified module proxy
+// URL, and copies the upstream response back to w.
+func proxyModule(w http.ResponseWriter, req *http.Request, target string) {
+	u, err := url.Parse(target)
+	if err != nil {
+		http.Error(w, "bad proxy target", http.StatusInternalServerError)
+		return
+	}
+	proxy := httputil.NewSingleHostReverseProxy(&url.URL{Scheme: u.Scheme, Host: u.Host})
+	director := proxy.Director
+	proxy.Director = func(r *http.Request) {
+		director(r)
+		r.URL.Path = u.Path
+		r.URL.RawPath = u.RawPath
+		r.Host = u.Host
+	}
+	proxy.ServeHTTP(w, req)
+}
@@ -0,0 +1,89 @@
+// Copyright 2018 Andrei Tudor Călin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vanity
+
+// SourceTag represents an HTML go-source meta tag, understood by
+// documentation sites such as godoc.org and pkg.go.dev in order to
+// build "jump to source" links for a package.
+//
+// The fields correspond to the four space-separated fields of the
+// go-source meta tag content, as documented at
+// https://github.com/golang/gddo/wiki/Source-Code-Links.
+type SourceTag struct {
+	// Prefix is the import path prefix that this SourceTag applies to.
+	Prefix string
+
+	// Home is the URL of the project home page.
+	Home string
+
+	// Directory is a template for directory listing pages. It contains
+	// the placeholder {dir}, replaced with the slash-separated
+	// directory path relative to Prefix, or {/dir}, which is the same
+	// path with a leading slash, or empty at the root. The presets
+	// below use {/dir}.
+	Directory string
+
+	// File is a template for source file pages. It contains the
+	// placeholders {dir} (or {/dir}), {file}, and {line}.
+	File string
+}
+
+// GitHubSource returns a SourceTag for a package hosted on GitHub, where
+// importPath is the vanity import path and repoURL is the URL of the
+// GitHub repository, e.g. "https://github.com/acln0/foo".
+func GitHubSource(importPath, repoURL string) *SourceTag {
+	return &SourceTag{
+		Prefix:    importPath,
+		Home:      repoURL,
+		Directory: repoURL + "/tree/master{/dir}",
+		File:      repoURL + "/blob/master{/dir}/{file}#L{line}",
+	}
+}
+
+// GitLabSource returns a SourceTag for a package hosted on GitLab, where
+// importPath is the vanity import path and repoURL is the URL of the
+// GitLab repository.
+func GitLabSource(importPath, repoURL string) *SourceTag {
+	return &SourceTag{
+		Prefix:    importPath,
+		Home:      repoURL,
+		Directory: repoURL + "/-/tree/master{/dir}",
+		File:      repoURL + "/-/blob/master{/dir}/{file}#L{line}",
+	}
+}
+
+// BitbucketSource returns a SourceTag for a package hosted on Bitbucket,
+// where importPath is the vanity import path and repoURL is the URL of
+// the Bitbucket repository.
+func BitbucketSource(importPath, repoURL string) *SourceTag {
+	return &SourceTag{
+		Prefix:    importPath,
+		Home:      repoURL,
+		Directory: repoURL + "/src/master{/dir}",
+		File:      repoURL + "/src/master{/dir}/{file}#lines-{line}",
+	}
+}
+
+// GiteaSource returns a SourceTag for a package hosted on a Gitea
+// instance, where importPath is the vanity import path and repoURL is
+// the URL of the Gitea repository.
+func GiteaSource(importPath, repoURL string) *SourceTag {
+	return &SourceTag{
+		Prefix:    importPath,
+		Home:      repoURL,
+		Directory: repoURL + "/src/branch/master{/dir}",
+		File:      repoURL + "/src/branch/master{/dir}/{file}#L{line}",
+	}
+}
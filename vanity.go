@@ -31,6 +31,27 @@ type ImportPath struct {
 	VCS  string
 	From string
 	To   string
+
+	// Source, if non-nil, is included in the ImportTag produced by
+	// TagFor and WildcardTagFor, causing a go-source meta tag to be
+	// rendered alongside the go-import tag.
+	Source *SourceTag
+
+	// Wildcard indicates that ip matches any immediate child of From,
+	// as documented on WildcardTagFor. It is consulted by Handler.
+	Wildcard bool
+
+	// Proxy, if non-empty, is the base URL of a Go module proxy backing
+	// this import path, such as "https://proxy.golang.org". When set,
+	// VCS is expected to be "mod", and Handler answers module proxy
+	// protocol requests (@v/list, @latest, @v/{version}.info|.mod|.zip)
+	// for this import path by proxying them to Proxy.
+	Proxy string
+
+	// Redirect, if non-nil, is called by Handler for interactive
+	// (non go-get) requests matching this ImportPath, overriding
+	// Handler.Redirect.
+	Redirect func(w http.ResponseWriter, req *http.Request)
 }
 
 // TagFor returns the import tag for the request req.
@@ -46,15 +67,28 @@ type ImportPath struct {
 func (ip ImportPath) TagFor(req *http.Request) (*ImportTag, error) {
 	p := path.Join(req.Host, req.URL.Path)
 	if p != ip.From && !strings.HasPrefix(p, ip.From+"/") {
-		return nil, fmt.Errorf("blah")
+		return nil, ErrPathMismatch
 	}
 	return &ImportTag{
 		ImportPath: ip.From,
 		VCS:        ip.VCS,
-		VCSRepo:    ip.To,
+		VCSRepo:    ip.vcsRepo(),
+		Source:     ip.Source,
 	}, nil
 }
 
+// vcsRepo returns the repository URL advertised in the go-import tag.
+// For VCS: "mod" entries backed by a module Proxy, it returns Proxy
+// rather than To, so that the advertised mod target always matches the
+// upstream that Handler actually forwards module proxy protocol
+// requests to; see proxyTarget.
+func (ip ImportPath) vcsRepo() string {
+	if ip.VCS == "mod" && ip.Proxy != "" {
+		return ip.Proxy
+	}
+	return ip.To
+}
+
 // WildcardTagFor returns the wildcard import tag for the request req.
 //
 // TagFor verifies that the import path specified by req is a strict sub-path
@@ -65,19 +99,33 @@ func (ip ImportPath) TagFor(req *http.Request) (*ImportTag, error) {
 //
 // For example, given ip.From == "acln.ro" and a request to "acln.ro/foo/bar",
 // the returned ImportTag would have .ImportPath == "acln.ro/foo".
+//
+// If ip.From is empty, req.Host is used as the root instead, so that a
+// single ImportPath can be reused across multiple hosts, as HostMux
+// does for HostDefaults. Any port in req.Host is ignored, since Go
+// import paths never carry one.
 func (ip ImportPath) WildcardTagFor(req *http.Request) (*ImportTag, error) {
-	p := path.Join(req.Host, req.URL.Path)
-	if !strings.HasPrefix(p, ip.From+"/") {
-		return nil, fmt.Errorf("blah")
+	host := stripPort(req.Host)
+	from := ip.From
+	if from == "" {
+		from = host
 	}
-	seg := p[len(ip.From)+1:]
+	p := path.Join(host, req.URL.Path)
+	if !strings.HasPrefix(p, from+"/") {
+		return nil, ErrPathMismatch
+	}
+	seg := p[len(from)+1:]
 	if i := strings.IndexByte(seg, '/'); i >= 0 {
 		seg = seg[:i]
 	}
+	if seg == "" {
+		return nil, ErrEmptyWildcardSegment
+	}
 	return &ImportTag{
-		ImportPath: path.Join(ip.From, seg),
+		ImportPath: path.Join(from, seg),
 		VCS:        ip.VCS,
-		VCSRepo:    path.Join(ip.To, seg),
+		VCSRepo:    ip.vcsRepo() + "/" + seg,
+		Source:     ip.Source,
 	}, nil
 }
 
@@ -86,6 +134,9 @@ var importTagTemplate = template.Must(template.New("meta").Parse(`
 <html>
 <head>
 	<meta name="go-import" content="{{ .ImportPath }} {{ .VCS }} {{ .VCSRepo }}">
+	{{- if .Source }}
+	<meta name="go-source" content="{{ .Source.Prefix }} {{ .Source.Home }} {{ .Source.Directory }} {{ .Source.File }}">
+	{{- end }}
 </head>
 </html>
 `))
@@ -95,6 +146,10 @@ type ImportTag struct {
 	ImportPath string
 	VCS        string
 	VCSRepo    string
+
+	// Source, if non-nil, causes Render to also emit a go-source meta
+	// tag, used by documentation sites to build source code links.
+	Source *SourceTag
 }
 
 // Render renders an HTML document to w, containing the go-import meta tag
@@ -114,15 +169,28 @@ var redirectTemplate = template.Must(template.New("redirect").Parse(`
 </html>
 `))
 
-// RedirectToGodoc redirects req to the corresponding godoc page.
+// RedirectToGodoc redirects req to the corresponding godoc.org page.
 //
 // The redirect URL is derived from req.Host and req.URL.Path. For
 // example, a request to example.com/foo/bar is redirected to
 // godoc.org/example.com/foo/bar.
 func RedirectToGodoc(w http.ResponseWriter, req *http.Request) {
+	redirectToDocs(w, req, "godoc.org")
+}
+
+// RedirectToPkgGoDev redirects req to the corresponding pkg.go.dev page.
+//
+// The redirect URL is derived from req.Host and req.URL.Path. For
+// example, a request to example.com/foo/bar is redirected to
+// pkg.go.dev/example.com/foo/bar.
+func RedirectToPkgGoDev(w http.ResponseWriter, req *http.Request) {
+	redirectToDocs(w, req, "pkg.go.dev")
+}
+
+func redirectToDocs(w http.ResponseWriter, req *http.Request, docsHost string) {
 	target := &url.URL{
 		Scheme: "https",
-		Host:   "godoc.org",
+		Host:   docsHost,
 		Path:   path.Join(req.Host, req.URL.Path),
 	}
 	resp := new(bytes.Buffer)
@@ -142,3 +210,11 @@ func RedirectToGodoc(w http.ResponseWriter, req *http.Request) {
 func IsGoGet(req *http.Request) bool {
 	return req.FormValue("go-get") == "1"
 }
+
+// stripPort returns host with any trailing ":port" removed.
+func stripPort(host string) string {
+	if i := strings.IndexByte(host, ':'); i >= 0 {
+		return host[:i]
+	}
+	return host
+}
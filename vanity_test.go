@@ -16,6 +16,7 @@ package vanity_test
 
 import (
 	"bytes"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
@@ -162,6 +163,50 @@ func TestImportTagRender(t *testing.T) {
 	}
 }
 
+func TestImportTagRenderWithSource(t *testing.T) {
+	tag := vanity.ImportTag{
+		ImportPath: "acln.ro/foo",
+		VCS:        "git",
+		VCSRepo:    "https://github.com/acln0/foo",
+		Source:     vanity.GitHubSource("acln.ro/foo", "https://github.com/acln0/foo"),
+	}
+	buf := new(bytes.Buffer)
+	if err := tag.Render(buf); err != nil {
+		t.Fatal(err)
+	}
+	want := `<meta name="go-source" content="acln.ro/foo https://github.com/acln0/foo https://github.com/acln0/foo/tree/master{/dir} https://github.com/acln0/foo/blob/master{/dir}/{file}#L{line}">`
+	if !strings.Contains(buf.String(), want) {
+		t.Fatalf("%s\ndoes not contain\n%s", buf.String(), want)
+	}
+}
+
+func TestTagForErrors(t *testing.T) {
+	p := vanity.ImportPath{VCS: "git", From: "acln.ro/foo", To: "https://github.com/acln0/foo"}
+	req, err := http.NewRequest("GET", "https://acln.ro/bar", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.TagFor(req); !errors.Is(err, vanity.ErrPathMismatch) {
+		t.Errorf("got %v, want ErrPathMismatch", err)
+	}
+}
+
+func TestWildcardTagForEmptySegment(t *testing.T) {
+	// An empty ip.From falls back to req.Host, as used by HostMux's
+	// HandleDefaults. A root request against an empty Host leaves no
+	// child segment to build a wildcard import path from.
+	p := vanity.ImportPath{VCS: "git", To: "https://github.com/example", Wildcard: true}
+	req, err := http.NewRequest("GET", "https://pkg.example.com/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Host = ""
+	req.URL.Path = "/"
+	if _, err := p.WildcardTagFor(req); !errors.Is(err, vanity.ErrEmptyWildcardSegment) {
+		t.Errorf("got %v, want ErrEmptyWildcardSegment", err)
+	}
+}
+
 func TestRedirectToGodoc(t *testing.T) {
 	importPath := "acln.ro/foo"
 	r, err := http.NewRequest("GET", importPath, nil)
@@ -0,0 +1,72 @@
+// Copyright 2018 Andrei Tudor Călin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vanity
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Watch watches the configuration file at path for changes, and calls
+// h.SetPaths with the reloaded routing table whenever the file is
+// written. In-flight requests are unaffected, since SetPaths swaps the
+// routing table atomically.
+//
+// If onError is non-nil, it is called with any error encountered while
+// watching or reloading path; Watch otherwise keeps serving the last
+// successfully loaded configuration. Watch blocks until ctx is done, at
+// which point it stops watching and returns ctx.Err().
+func Watch(ctx context.Context, path string, h *Handler, onError func(error)) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("vanity: watching %s: %w", path, err)
+	}
+	defer watcher.Close()
+
+	if err := watcher.Add(path); err != nil {
+		return fmt.Errorf("vanity: watching %s: %w", path, err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case ev, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if ev.Op&(fsnotify.Write|fsnotify.Create) == 0 {
+				continue
+			}
+			reloaded, err := LoadConfig(path)
+			if err != nil {
+				if onError != nil {
+					onError(err)
+				}
+				continue
+			}
+			h.SetPaths(reloaded.routes()...)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			if onError != nil {
+				onError(err)
+			}
+		}
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright 2018 Andrei Tudor Călin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vanity_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"acln.ro/vanity"
+)
+
+func TestWatch(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "vanity.json")
+	const initial = `{"paths":[{"from":"acln.ro/foo","to":"https://github.com/acln0/foo","vcs":"git"}]}`
+	if err := writeFile(configPath, initial); err != nil {
+		t.Fatal(err)
+	}
+
+	h, err := vanity.LoadConfig(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- vanity.Watch(ctx, configPath, h, nil) }()
+
+	// Give the watcher time to start watching configPath before the
+	// file is rewritten, so the write isn't missed.
+	time.Sleep(50 * time.Millisecond)
+	const updated = `{"paths":[{"from":"acln.ro/bar","to":"https://github.com/acln0/bar","vcs":"git"}]}`
+	if err := writeFile(configPath, updated); err != nil {
+		t.Fatal(err)
+	}
+
+	served := func() bool {
+		req := httptest.NewRequest("GET", "https://acln.ro/bar?go-get=1", nil)
+		req.TLS = tlsConnectionState()
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w.Result().StatusCode == http.StatusOK
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for !served() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for Watch to reload the updated config")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("got %v, want context.Canceled", err)
+	}
+}